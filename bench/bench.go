@@ -0,0 +1,282 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	_grpc "github.com/Code-Hex/grpcrnd/grpc"
+
+	"github.com/Code-Hex/grpcrnd/call"
+	"github.com/Code-Hex/grpcrnd/reflect"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type CommandRunner interface {
+	Run() func(cmd *cobra.Command, args []string) error
+	Command() *cobra.Command
+}
+
+type command struct {
+	cmd         *cobra.Command
+	insecure    *bool
+	headers     []string
+	concurrency int
+	duration    time.Duration
+	requests    int
+	rps         float64
+	expectCode  []string
+	unmarshaler *jsonpb.Unmarshaler
+
+	seed uint64
+	rand *call.Rand
+	mu   sync.Mutex
+}
+
+func New(insecure *bool) CommandRunner {
+	c := &command{
+		cmd: &cobra.Command{
+			Use:   "bench <addr> <method>",
+			Short: "drive concurrent load against a gRPC method using generated random parameters",
+			Example: `
+* bench for 30s with 10 concurrent workers
+grpcrnd bench localhost:8888 test.Test.Echo -c 10 -d 30s
+
+* send exactly 1000 requests and fail if anything but OK is observed
+grpcrnd bench localhost:8888 test.Test.Echo -n 1000 --expect-code=OK
+`,
+			Args:         cobra.ExactArgs(2),
+			SilenceUsage: true,
+		},
+		insecure: insecure,
+		unmarshaler: &jsonpb.Unmarshaler{
+			AllowUnknownFields: true,
+		},
+	}
+	c.cmd.RunE = c.Run()
+	c.cmd.Flags().StringArrayVarP(&c.headers, "header", "H", nil, "send with header")
+	c.cmd.Flags().IntVarP(&c.concurrency, "concurrency", "c", 1, "number of concurrent workers")
+	c.cmd.Flags().DurationVarP(&c.duration, "duration", "d", 0, "how long to run the benchmark, e.g. 30s")
+	c.cmd.Flags().IntVarP(&c.requests, "requests", "n", 0, "total number of requests to send, across all workers")
+	c.cmd.Flags().Float64Var(&c.rps, "rps", 0, "token-bucket rate limit across all workers, in requests per second (0 = unlimited)")
+	c.cmd.Flags().StringArrayVar(&c.expectCode, "expect-code", nil, "gRPC status code(s) the run must only observe, e.g. OK (repeatable)")
+	c.cmd.Flags().Uint64Var(&c.seed, "seed", 0, "seed the random generator for reproducible output; 0 picks a random seed and prints it to stderr")
+	return c
+}
+
+func (c *command) Command() *cobra.Command { return c.cmd }
+
+func (c *command) Run() func(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	return func(cmd *cobra.Command, args []string) error {
+		seed := c.seed
+		if seed == 0 {
+			seed = uint64(time.Now().UnixNano())
+		}
+		fmt.Fprintf(os.Stderr, "seed: %d\n", seed)
+		c.rand = call.NewSeededRand(seed)
+
+		conn, err := _grpc.NewClientConnection(ctx, args[0], *c.insecure, nil)
+		if err != nil {
+			return errors.Wrap(err, "failed to make a gRPC connection")
+		}
+		defer conn.Close()
+		client := reflect.NewGRPCClient(ctx, conn)
+		return c.Bench(client, args[1])
+	}
+}
+
+// Bench resolves reflectionMethod and drives load against it, printing a
+// latency/status-code report when it finishes.
+func (c *command) Bench(client *reflect.Client, reflectionMethod string) error {
+	service, method, err := call.DetectServiceMethod(reflectionMethod)
+	if err != nil {
+		return errors.Wrap(err, "unexpected format")
+	}
+	svc, err := client.ResolveService(service)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve service %s", service)
+	}
+	mdesc := svc.FindMethodByName(method)
+	if mdesc == nil {
+		return errors.New("method couldn't be found")
+	}
+	if mdesc.IsClientStreaming() || mdesc.IsServerStreaming() {
+		return errors.New("bench only supports unary methods")
+	}
+	if c.requests == 0 && c.duration == 0 {
+		c.duration = 10 * time.Second
+	}
+
+	result := c.drive(client, mdesc)
+	result.print()
+	return result.checkExpectedCodes(c.expectCode)
+}
+
+// drive spawns c.concurrency workers against a single shared connection,
+// each repeatedly generating a random request off c.rand, invoking mdesc,
+// and recording latency/status until the duration or request budget runs
+// out. c.rand is shared and mutex-guarded so a run started with a given
+// --seed is reproducible regardless of worker scheduling.
+func (c *command) drive(client *reflect.Client, mdesc *desc.MethodDescriptor) *result {
+	r := newResult()
+
+	var limiter *rate.Limiter
+	if c.rps > 0 {
+		limiter = rate.NewLimiter(rate.Limit(c.rps), 1)
+	}
+
+	ctx := context.Background()
+	md := call.BuildOutgoingMetadata(c.headers)
+	pool := sync.Pool{
+		New: func() interface{} { return dynamic.NewMessage(mdesc.GetInputType()) },
+	}
+
+	var deadline time.Time
+	if c.duration > 0 {
+		deadline = time.Now().Add(c.duration)
+	}
+	var sent int64
+
+	worker := func() {
+		for {
+			if c.requests > 0 && atomic.AddInt64(&sent, 1) > int64(c.requests) {
+				return
+			}
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return
+			}
+			if limiter != nil {
+				if err := limiter.Wait(ctx); err != nil {
+					return
+				}
+			}
+
+			msg := pool.Get().(*dynamic.Message)
+			c.mu.Lock()
+			popErr := call.PopulateMessage(c.rand, msg, client, c.unmarshaler, "")
+			c.mu.Unlock()
+			if popErr != nil {
+				pool.Put(msg)
+				r.record(0, codes.Internal)
+				continue
+			}
+
+			start := time.Now()
+			_, err := client.InvokeRPC(metadata.NewOutgoingContext(ctx, md), mdesc, msg)
+			latency := time.Since(start)
+			pool.Put(msg)
+
+			st, _ := status.FromError(err)
+			r.record(latency, st.Code())
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(c.concurrency)
+	for i := 0; i < c.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			worker()
+		}()
+	}
+	wg.Wait()
+
+	return r
+}
+
+// result accumulates per-call latencies and status codes from every worker.
+type result struct {
+	mu      sync.Mutex
+	latency []time.Duration
+	byCode  map[codes.Code]int
+}
+
+func newResult() *result {
+	return &result{byCode: make(map[codes.Code]int)}
+}
+
+func (r *result) record(d time.Duration, code codes.Code) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latency = append(r.latency, d)
+	r.byCode[code]++
+}
+
+// percentile returns the p-th percentile latency (p in [0,1]).
+func (r *result) percentile(p float64) time.Duration {
+	if len(r.latency) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), r.latency...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+func (r *result) print() {
+	fmt.Printf("requests: %d\n", len(r.latency))
+	fmt.Printf("latency:  p50=%s p90=%s p99=%s\n", r.percentile(0.5), r.percentile(0.9), r.percentile(0.99))
+	fmt.Println("status codes:")
+	for code, n := range r.byCode {
+		fmt.Printf("  %-20s %d\n", code, n)
+	}
+}
+
+// codeByName maps the --expect-code flag's names, the upper-snake-case status
+// code names of the gRPC spec such as INVALID_ARGUMENT, back to codes.Code.
+// These differ from the CamelCase spelling codes.Code.String() prints.
+var codeByName = map[string]codes.Code{
+	"OK":                  codes.OK,
+	"CANCELLED":           codes.Canceled,
+	"UNKNOWN":             codes.Unknown,
+	"INVALID_ARGUMENT":    codes.InvalidArgument,
+	"DEADLINE_EXCEEDED":   codes.DeadlineExceeded,
+	"NOT_FOUND":           codes.NotFound,
+	"ALREADY_EXISTS":      codes.AlreadyExists,
+	"PERMISSION_DENIED":   codes.PermissionDenied,
+	"RESOURCE_EXHAUSTED":  codes.ResourceExhausted,
+	"FAILED_PRECONDITION": codes.FailedPrecondition,
+	"ABORTED":             codes.Aborted,
+	"OUT_OF_RANGE":        codes.OutOfRange,
+	"UNIMPLEMENTED":       codes.Unimplemented,
+	"INTERNAL":            codes.Internal,
+	"UNAVAILABLE":         codes.Unavailable,
+	"DATA_LOSS":           codes.DataLoss,
+	"UNAUTHENTICATED":     codes.Unauthenticated,
+}
+
+// checkExpectedCodes fails the run if any status code outside of expect was
+// observed. An empty expect leaves the result unchecked.
+func (r *result) checkExpectedCodes(expect []string) error {
+	if len(expect) == 0 {
+		return nil
+	}
+	allowed := make(map[codes.Code]bool, len(expect))
+	for _, name := range expect {
+		code, ok := codeByName[strings.ToUpper(name)]
+		if !ok {
+			return errors.Errorf("unknown --expect-code %q", name)
+		}
+		allowed[code] = true
+	}
+	for code, n := range r.byCode {
+		if n > 0 && !allowed[code] {
+			return errors.Errorf("observed unexpected status code %s (%d occurrences)", code, n)
+		}
+	}
+	return nil
+}
@@ -3,10 +3,16 @@ package call
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
 	"strings"
+	"time"
 
 	_grpc "github.com/Code-Hex/grpcrnd/grpc"
 
+	"github.com/Code-Hex/grpcrnd/auth"
 	"github.com/Code-Hex/grpcrnd/reflect"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/protoc-gen-go/descriptor"
@@ -16,6 +22,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
@@ -30,8 +37,31 @@ type command struct {
 	insecure    *bool
 	headers     []string
 	uselog      bool
+	streamCount int
+	anyType     string
 	marshaler   *jsonpb.Marshaler
 	unmarshaler *jsonpb.Unmarshaler
+
+	seed        uint64
+	rand        *Rand
+	saveRequest string
+	requestFile string
+
+	// auth
+	bearer         string
+	bearerFromFile string
+	oauth2TokenURL string
+	clientID       string
+	clientSecret   string
+	jwtSignKey     string
+	jwtClaims      string
+	jwtTTL         time.Duration
+
+	// transport
+	cacert     string
+	cert       string
+	key        string
+	serverName string
 }
 
 func New(insecure *bool) CommandRunner {
@@ -61,6 +91,25 @@ grpcrnd call localhost:8888 test.Test.Echo -H 'UserAgent: grpcrand'
 	c.cmd.RunE = c.Run()
 	c.cmd.Flags().StringArrayVarP(&c.headers, "header", "H", nil, "send with header")
 	c.cmd.Flags().BoolVarP(&c.uselog, "log", "l", false, "specify if you want to output to logs")
+	c.cmd.Flags().IntVar(&c.streamCount, "stream-count", 3, "number of randomly generated messages to send for client/bidi streaming RPCs")
+	c.cmd.Flags().StringVar(&c.anyType, "any-type", "", "fully-qualified message name to pack when generating a google.protobuf.Any field")
+	c.cmd.Flags().Uint64Var(&c.seed, "seed", 0, "seed the random generator for reproducible output; 0 picks a random seed and prints it to stderr")
+	c.cmd.Flags().StringVar(&c.saveRequest, "save-request", "", "write the generated request payload as JSON to this path")
+	c.cmd.Flags().StringVar(&c.requestFile, "request", "", "replay a payload previously written by --save-request instead of generating one")
+
+	c.cmd.Flags().StringVar(&c.bearer, "bearer", "", "send this bearer token as the authorization header")
+	c.cmd.Flags().StringVar(&c.bearerFromFile, "bearer-from-file", "", "read a bearer token to send as the authorization header from a file")
+	c.cmd.Flags().StringVar(&c.oauth2TokenURL, "oauth2-token-url", "", "fetch a bearer token via the OAuth2 client-credentials flow from this token URL")
+	c.cmd.Flags().StringVar(&c.clientID, "client-id", "", "OAuth2 client ID, used with --oauth2-token-url")
+	c.cmd.Flags().StringVar(&c.clientSecret, "client-secret", "", "OAuth2 client secret, used with --oauth2-token-url")
+	c.cmd.Flags().StringVar(&c.jwtSignKey, "jwt-sign", "", "path to an RSA or EC PEM private key; mint and send a short-lived JWT signed with it on every call")
+	c.cmd.Flags().StringVar(&c.jwtClaims, "jwt-claims", "", "optional path to a JSON file of base claims to include in the --jwt-sign token")
+	c.cmd.Flags().DurationVar(&c.jwtTTL, "jwt-ttl", time.Minute, "lifetime of the token minted by --jwt-sign")
+
+	c.cmd.Flags().StringVar(&c.cacert, "cacert", "", "path to a PEM CA certificate to verify the server against, instead of the system pool")
+	c.cmd.Flags().StringVar(&c.cert, "cert", "", "path to a PEM client certificate for mutual TLS, used with --key")
+	c.cmd.Flags().StringVar(&c.key, "key", "", "path to a PEM client private key for mutual TLS, used with --cert")
+	c.cmd.Flags().StringVar(&c.serverName, "server-name", "", "override the server name used to verify the TLS certificate")
 	return c
 }
 
@@ -69,7 +118,24 @@ func (c *command) Command() *cobra.Command { return c.cmd }
 func (c *command) Run() func(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	return func(cmd *cobra.Command, args []string) error {
-		conn, err := _grpc.NewClientConnection(ctx, args[0], *c.insecure)
+		seed := c.seed
+		if seed == 0 {
+			seed = NewRand().uint64()
+		}
+		fmt.Fprintf(os.Stderr, "seed: %d\n", seed)
+		c.rand = NewSeededRand(seed)
+
+		perRPC, err := c.buildPerRPCCredentials()
+		if err != nil {
+			return errors.Wrap(err, "failed to build call credentials")
+		}
+		tlsConfig := &_grpc.TLSConfig{
+			CACert:     c.cacert,
+			Cert:       c.cert,
+			Key:        c.key,
+			ServerName: c.serverName,
+		}
+		conn, err := _grpc.NewClientConnection(ctx, args[0], *c.insecure, tlsConfig, perRPC...)
 		if err != nil {
 			return errors.Wrap(err, "failed to make a gRPC connection")
 		}
@@ -82,7 +148,38 @@ func (c *command) Run() func(cmd *cobra.Command, args []string) error {
 	}
 }
 
-func detectServiceMethod(reflectionMethod string) (string, string, error) {
+// buildPerRPCCredentials assembles the PerRPCCredentials implied by
+// whichever of --bearer, --bearer-from-file, --oauth2-token-url, or
+// --jwt-sign was set, so it composes with --header instead of replacing it.
+// Only one auth mode is used: when several are set, the first in that order
+// wins and the others are ignored.
+func (c *command) buildPerRPCCredentials() ([]credentials.PerRPCCredentials, error) {
+	var creds []credentials.PerRPCCredentials
+	switch {
+	case c.bearer != "":
+		creds = append(creds, auth.BearerToken(c.bearer))
+	case c.bearerFromFile != "":
+		cred, err := auth.BearerTokenFromFile(c.bearerFromFile)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, cred)
+	case c.oauth2TokenURL != "":
+		creds = append(creds, auth.OAuth2ClientCredentials(c.oauth2TokenURL, c.clientID, c.clientSecret, nil))
+	case c.jwtSignKey != "":
+		signer, err := auth.NewJWTSigner(c.jwtSignKey, c.jwtClaims, c.jwtTTL)
+		if err != nil {
+			return nil, err
+		}
+		creds = append(creds, signer)
+	}
+	return creds, nil
+}
+
+// DetectServiceMethod splits a fully-qualified reflection method name such
+// as "test.Test.Echo" into its service ("test.Test") and method ("Echo")
+// parts.
+func DetectServiceMethod(reflectionMethod string) (string, string, error) {
 	n := strings.LastIndex(reflectionMethod, ".")
 	if n < 0 {
 		return "", "", errors.Errorf("invalid reflection method name: %s", reflectionMethod)
@@ -93,7 +190,7 @@ func detectServiceMethod(reflectionMethod string) (string, string, error) {
 }
 
 func (c *command) Call(client *reflect.Client, reflectionMethod string) error {
-	service, method, err := detectServiceMethod(reflectionMethod)
+	service, method, err := DetectServiceMethod(reflectionMethod)
 	if err != nil {
 		return errors.Wrap(err, "unexpected format")
 	}
@@ -105,7 +202,21 @@ func (c *command) Call(client *reflect.Client, reflectionMethod string) error {
 	if mdesc == nil {
 		return errors.New("method couldn't be found")
 	}
-	msg, err := c.createMessage(mdesc)
+
+	switch {
+	case mdesc.IsClientStreaming() && mdesc.IsServerStreaming():
+		return c.callBidiStreaming(client, mdesc)
+	case mdesc.IsClientStreaming():
+		return c.callClientStreaming(client, mdesc)
+	case mdesc.IsServerStreaming():
+		return c.callServerStreaming(client, mdesc)
+	default:
+		return c.callUnary(client, reflectionMethod, mdesc)
+	}
+}
+
+func (c *command) callUnary(client *reflect.Client, reflectionMethod string, mdesc *desc.MethodDescriptor) error {
+	msg, err := c.createMessage(c.rand, client, mdesc)
 	if err != nil {
 		return errors.Wrap(err, "failed to create message")
 	}
@@ -120,7 +231,7 @@ func (c *command) Call(client *reflect.Client, reflectionMethod string) error {
 		pp.Println(string(reqJSON))
 	}
 
-	ctx := metadata.NewOutgoingContext(context.Background(), buildOutgoingMetadata(c.headers))
+	ctx := metadata.NewOutgoingContext(context.Background(), BuildOutgoingMetadata(c.headers))
 
 	var headerMD metadata.MD
 	var trailerMD metadata.MD
@@ -144,7 +255,139 @@ func (c *command) Call(client *reflect.Client, reflectionMethod string) error {
 	return nil
 }
 
-func buildOutgoingMetadata(header []string) metadata.MD {
+// callClientStreaming generates c.streamCount random request messages, sends
+// them over a client-streaming RPC, and emits the single response.
+func (c *command) callClientStreaming(client *reflect.Client, mdesc *desc.MethodDescriptor) error {
+	ctx := metadata.NewOutgoingContext(context.Background(), BuildOutgoingMetadata(c.headers))
+
+	stream, err := client.Stub().InvokeRpcClientStream(ctx, mdesc)
+	if err != nil {
+		return errors.Wrap(err, "failed to open client stream")
+	}
+
+	for i := 0; i < c.streamCount; i++ {
+		msg, err := c.createMessage(c.rand, client, mdesc)
+		if err != nil {
+			return errors.Wrap(err, "failed to create message")
+		}
+		if err := stream.SendMsg(msg); err != nil {
+			return errors.Wrap(err, "failed to send stream message")
+		}
+	}
+
+	resp, err := stream.CloseAndReceive()
+	if err != nil {
+		st, ok := status.FromError(err)
+		if !ok {
+			return errors.Wrap(err, "failed to get error from proto")
+		}
+		resp = st.Proto()
+	}
+
+	respJSON, err := c.marshaler.MarshalToString(resp)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal json response")
+	}
+	return c.output(respJSON)
+}
+
+// callServerStreaming sends a single random request and streams every
+// response frame to c.output as NDJSON until the server closes the stream.
+func (c *command) callServerStreaming(client *reflect.Client, mdesc *desc.MethodDescriptor) error {
+	msg, err := c.createMessage(c.rand, client, mdesc)
+	if err != nil {
+		return errors.Wrap(err, "failed to create message")
+	}
+
+	ctx := metadata.NewOutgoingContext(context.Background(), BuildOutgoingMetadata(c.headers))
+	stream, err := client.Stub().InvokeRpcServerStream(ctx, mdesc, msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to open server stream")
+	}
+
+	for {
+		resp, err := stream.RecvMsg()
+		terminal := false
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			st, ok := status.FromError(err)
+			if !ok {
+				return errors.Wrap(err, "failed to get error from proto")
+			}
+			resp = st.Proto()
+			terminal = true
+		}
+		respJSON, err := c.marshaler.MarshalToString(resp)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal json frame")
+		}
+		if err := c.output(respJSON); err != nil {
+			return errors.Wrap(err, "failed to write log")
+		}
+		if terminal {
+			return nil
+		}
+	}
+}
+
+// callBidiStreaming concurrently sends c.streamCount random request messages
+// while streaming every response frame to c.output as NDJSON.
+func (c *command) callBidiStreaming(client *reflect.Client, mdesc *desc.MethodDescriptor) error {
+	ctx := metadata.NewOutgoingContext(context.Background(), BuildOutgoingMetadata(c.headers))
+	stream, err := client.Stub().InvokeRpcBidiStream(ctx, mdesc)
+	if err != nil {
+		return errors.Wrap(err, "failed to open bidi stream")
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		for i := 0; i < c.streamCount; i++ {
+			msg, err := c.createMessage(c.rand, client, mdesc)
+			if err != nil {
+				sendErr <- errors.Wrap(err, "failed to create message")
+				return
+			}
+			if err := stream.SendMsg(msg); err != nil {
+				sendErr <- errors.Wrap(err, "failed to send stream message")
+				return
+			}
+		}
+		sendErr <- stream.CloseSend()
+	}()
+
+	for {
+		resp, err := stream.RecvMsg()
+		terminal := false
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			st, ok := status.FromError(err)
+			if !ok {
+				return errors.Wrap(err, "failed to get error from proto")
+			}
+			resp = st.Proto()
+			terminal = true
+		}
+		respJSON, err := c.marshaler.MarshalToString(resp)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal json frame")
+		}
+		if err := c.output(respJSON); err != nil {
+			return errors.Wrap(err, "failed to write log")
+		}
+		if terminal {
+			break
+		}
+	}
+	return <-sendErr
+}
+
+// BuildOutgoingMetadata turns "key: value" header strings, as accepted by
+// the --header flag, into outgoing gRPC metadata.
+func BuildOutgoingMetadata(header []string) metadata.MD {
 	var pairs []string
 	for i := range header {
 		parts := strings.SplitN(header[i], ":", 2)
@@ -157,63 +400,350 @@ func buildOutgoingMetadata(header []string) metadata.MD {
 	return metadata.Pairs(pairs...)
 }
 
-func (c *command) createMessage(mdesc *desc.MethodDescriptor) (*dynamic.Message, error) {
+func (c *command) createMessage(r *Rand, client *reflect.Client, mdesc *desc.MethodDescriptor) (*dynamic.Message, error) {
+	if c.requestFile != "" {
+		return c.loadMessage(mdesc)
+	}
+	msg, err := CreateMessage(r, client, mdesc, c.unmarshaler, c.anyType)
+	if err != nil {
+		return nil, err
+	}
+	if c.saveRequest != "" {
+		if err := c.writeRequest(msg); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// loadMessage reads the JSON payload at c.requestFile, as previously written
+// by --save-request, and unmarshals it directly into mdesc's input type,
+// skipping retriveFields entirely so a hand-edited payload replays exactly
+// as edited.
+func (c *command) loadMessage(mdesc *desc.MethodDescriptor) (*dynamic.Message, error) {
+	b, err := ioutil.ReadFile(c.requestFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read --request file")
+	}
 	msg := dynamic.NewMessage(mdesc.GetInputType())
-	m := retriveFields(msg.GetKnownFields())
-	param, err := json.Marshal(&m)
+	if err := msg.UnmarshalJSONPB(c.unmarshaler, b); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal --request file")
+	}
+	return msg, nil
+}
+
+// writeRequest marshals msg to c.saveRequest so a later invocation can
+// replay the exact same payload via --request.
+func (c *command) writeRequest(msg *dynamic.Message) error {
+	b, err := msg.MarshalJSONPB(c.marshaler)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create param json")
+		return errors.Wrap(err, "failed to marshal request for --save-request")
 	}
-	if err := msg.UnmarshalJSONPB(c.unmarshaler, param); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal to protobuf json")
+	if err := ioutil.WriteFile(c.saveRequest, b, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write --save-request file")
+	}
+	return nil
+}
+
+// CreateMessage builds a randomly populated request message for mdesc,
+// honoring any google.protobuf.Any field by packing anyType (see the
+// --any-type flag). It is exported so sibling commands, such as bench, can
+// generate request payloads the same way call does.
+func CreateMessage(r *Rand, client *reflect.Client, mdesc *desc.MethodDescriptor, unmarshaler *jsonpb.Unmarshaler, anyType string) (*dynamic.Message, error) {
+	msg := dynamic.NewMessage(mdesc.GetInputType())
+	if err := PopulateMessage(r, msg, client, unmarshaler, anyType); err != nil {
+		return nil, err
 	}
 	return msg, nil
 }
 
-func retriveFields(fields []*desc.FieldDescriptor) map[string]interface{} {
+// PopulateMessage fills an already-allocated message with random values,
+// instead of allocating a new one the way CreateMessage does. Callers that
+// need to stay allocation-light on a hot path, such as bench, can draw msg
+// from a sync.Pool and reuse it across invocations.
+func PopulateMessage(r *Rand, msg *dynamic.Message, client *reflect.Client, unmarshaler *jsonpb.Unmarshaler, anyType string) error {
+	msg.Reset()
+	c := &command{anyType: anyType}
+	m := c.retriveFields(r, client, msg.GetKnownFields(), 0)
+	param, err := json.Marshal(&m)
+	if err != nil {
+		return errors.Wrap(err, "failed to create param json")
+	}
+	if err := msg.UnmarshalJSONPB(unmarshaler, param); err != nil {
+		return errors.Wrap(err, "failed to unmarshal to protobuf json")
+	}
+	return nil
+}
+
+// maxMessageDepth bounds recursion into nested message fields so
+// self-referential message types (e.g. a tree node pointing at itself)
+// can't send retriveFields into an infinite loop.
+const maxMessageDepth = 8
+
+func (c *command) retriveFields(r *Rand, client *reflect.Client, fields []*desc.FieldDescriptor, depth int) map[string]interface{} {
 	m := make(map[string]interface{}, 0)
+	oneOfPick := make(map[*desc.OneOfDescriptor]*desc.FieldDescriptor)
 	for _, field := range fields {
-		key := field.GetJSONName()
-		r := NewRand()
-		// https://github.com/golang/protobuf/blob/157d9c53be5810dd5a0fac4a467f7d5f400042ea/protoc-gen-go/descriptor/descriptor.pb.go#L51-L81
-		switch *field.GetType().Enum() {
-		case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
-			m[key] = r.double()
-		case descriptor.FieldDescriptorProto_TYPE_FLOAT:
-			m[key] = r.float()
-		case descriptor.FieldDescriptorProto_TYPE_UINT32:
-			m[key] = r.uint32()
-		case descriptor.FieldDescriptorProto_TYPE_UINT64:
-			m[key] = r.uint64()
-		case descriptor.FieldDescriptorProto_TYPE_INT32,
-			descriptor.FieldDescriptorProto_TYPE_FIXED32,
-			descriptor.FieldDescriptorProto_TYPE_SFIXED32,
-			descriptor.FieldDescriptorProto_TYPE_SINT32:
-			m[key] = r.int32()
-		case descriptor.FieldDescriptorProto_TYPE_INT64,
-			descriptor.FieldDescriptorProto_TYPE_FIXED64,
-			descriptor.FieldDescriptorProto_TYPE_SFIXED64,
-			descriptor.FieldDescriptorProto_TYPE_SINT64:
-			m[key] = r.int64()
-		case descriptor.FieldDescriptorProto_TYPE_BOOL:
-			m[key] = r.bool()
-		case descriptor.FieldDescriptorProto_TYPE_BYTES:
-			m[key] = r.bytes()
-		case descriptor.FieldDescriptorProto_TYPE_STRING:
-			m[key] = r.string()
-		// Group is deprecated in proto3.
-		// case descriptor.FieldDescriptorProto_TYPE_GROUP:
-		case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
-			msg := field.GetMessageType()
-			m[key] = retriveFields(msg.GetFields())
-		case descriptor.FieldDescriptorProto_TYPE_ENUM:
-			enum := field.GetEnumType().GetValues()
-			num := len(enum)
-			idx := r.pickupEnum(num)
-			m[key] = enum[idx].GetNumber()
-		default:
-			// TODO: oneof ...???
+		if oneof := field.GetOneOf(); oneof != nil {
+			pick, ok := oneOfPick[oneof]
+			if !ok {
+				choices := oneof.GetChoices()
+				pick = choices[r.pickupEnum(len(choices))]
+				oneOfPick[oneof] = pick
+			}
+			if field != pick {
+				// Skip siblings so UnmarshalJSONPB doesn't reject conflicting fields.
+				continue
+			}
+		}
+		m[field.GetJSONName()] = c.retriveFieldValue(r, client, field, depth)
+	}
+	return m
+}
+
+// retriveFieldValue generates a value for a single field, expanding
+// repeated and map fields into their JSON collection shapes.
+func (c *command) retriveFieldValue(r *Rand, client *reflect.Client, field *desc.FieldDescriptor, depth int) interface{} {
+	if field.IsMap() {
+		keyField := field.GetMapKeyType()
+		valField := field.GetMapValueType()
+		n := 1 + r.pickupEnum(3)
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			key := fmt.Sprintf("%v", c.retriveScalarOrMessage(r, client, keyField, depth))
+			m[key] = c.retriveScalarOrMessage(r, client, valField, depth)
+		}
+		return m
+	}
+	if field.IsRepeated() {
+		n := 1 + r.pickupEnum(5)
+		if rule, ok := repeatedRuleFor(field); ok {
+			n = rule.itemCount(r)
+		}
+		arr := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			arr = append(arr, c.retriveScalarOrMessage(r, client, field, depth))
+		}
+		return arr
+	}
+	return c.retriveScalarOrMessage(r, client, field, depth)
+}
+
+// retriveScalarOrMessage generates a single element value for field,
+// i.e. field's value with any repeated/map-ness already stripped away.
+func (c *command) retriveScalarOrMessage(r *Rand, client *reflect.Client, field *desc.FieldDescriptor, depth int) interface{} {
+	// https://github.com/golang/protobuf/blob/157d9c53be5810dd5a0fac4a467f7d5f400042ea/protoc-gen-go/descriptor/descriptor.pb.go#L51-L81
+	switch *field.GetType().Enum() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		if rule, ok := numericRuleFor(field); ok {
+			return rule.clampedFloat(r, r.double())
+		}
+		return r.double()
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		if rule, ok := numericRuleFor(field); ok {
+			return float32(rule.clampedFloat(r, float64(r.float())))
+		}
+		return r.float()
+	case descriptor.FieldDescriptorProto_TYPE_UINT32:
+		if rule, ok := numericRuleFor(field); ok {
+			return uint32(rule.clampedFloat(r, float64(r.uint32())))
+		}
+		return r.uint32()
+	case descriptor.FieldDescriptorProto_TYPE_UINT64:
+		if rule, ok := numericRuleFor(field); ok {
+			return uint64(rule.clampedFloat(r, float64(r.uint64())))
+		}
+		return r.uint64()
+	case descriptor.FieldDescriptorProto_TYPE_INT32,
+		descriptor.FieldDescriptorProto_TYPE_FIXED32,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED32,
+		descriptor.FieldDescriptorProto_TYPE_SINT32:
+		if rule, ok := numericRuleFor(field); ok {
+			return int32(rule.clampedFloat(r, float64(r.int32())))
+		}
+		return r.int32()
+	case descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64,
+		descriptor.FieldDescriptorProto_TYPE_SINT64:
+		if rule, ok := numericRuleFor(field); ok {
+			return int64(rule.clampedFloat(r, float64(r.int64())))
+		}
+		return r.int64()
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return r.bool()
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return r.bytes()
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		if rule, ok := stringRuleFor(field); ok {
+			return rule.randomString(r)
+		}
+		return r.string()
+	// Group is deprecated in proto3.
+	// case descriptor.FieldDescriptorProto_TYPE_GROUP:
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		return c.retriveMessageValue(r, client, field.GetMessageType(), depth)
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		enum := field.GetEnumType().GetValues()
+		idx := r.pickupEnum(len(enum))
+		return enum[idx].GetNumber()
+	default:
+		return nil
+	}
+}
+
+// Well-known message type names jsonpb marshals/unmarshals specially, as
+// opposed to their literal field-by-field JSON representation.
+const (
+	wktTimestamp   = "google.protobuf.Timestamp"
+	wktDuration    = "google.protobuf.Duration"
+	wktAny         = "google.protobuf.Any"
+	wktStruct      = "google.protobuf.Struct"
+	wktValue       = "google.protobuf.Value"
+	wktListValue   = "google.protobuf.ListValue"
+	wktFieldMask   = "google.protobuf.FieldMask"
+	wktDoubleValue = "google.protobuf.DoubleValue"
+	wktFloatValue  = "google.protobuf.FloatValue"
+	wktInt64Value  = "google.protobuf.Int64Value"
+	wktUInt64Value = "google.protobuf.UInt64Value"
+	wktInt32Value  = "google.protobuf.Int32Value"
+	wktUInt32Value = "google.protobuf.UInt32Value"
+	wktBoolValue   = "google.protobuf.BoolValue"
+	wktStringValue = "google.protobuf.StringValue"
+	wktBytesValue  = "google.protobuf.BytesValue"
+)
+
+// retriveMessageValue generates a value for a nested message field. It
+// special-cases the well-known types jsonpb marshals as something other
+// than their literal fields, and otherwise falls back to recursing into
+// the message's own fields.
+func (c *command) retriveMessageValue(r *Rand, client *reflect.Client, mdesc *desc.MessageDescriptor, depth int) interface{} {
+	switch mdesc.GetFullyQualifiedName() {
+	case wktTimestamp:
+		return randomTimestamp(r)
+	case wktDuration:
+		return randomDuration(r)
+	case wktAny:
+		return c.randomAny(r, client, depth)
+	case wktStruct:
+		return randomStruct(r)
+	case wktValue:
+		return randomValue(r)
+	case wktListValue:
+		return randomListValue(r)
+	case wktFieldMask:
+		return randomFieldMask(r)
+	case wktDoubleValue:
+		return r.double()
+	case wktFloatValue:
+		return r.float()
+	case wktInt64Value:
+		return r.int64()
+	case wktUInt64Value:
+		return r.uint64()
+	case wktInt32Value:
+		return r.int32()
+	case wktUInt32Value:
+		return r.uint32()
+	case wktBoolValue:
+		return r.bool()
+	case wktStringValue:
+		return r.string()
+	case wktBytesValue:
+		return r.bytes()
+	}
+	if depth >= maxMessageDepth {
+		return map[string]interface{}{}
+	}
+	return c.retriveFields(r, client, mdesc.GetFields(), depth+1)
+}
+
+// randomTimestamp returns an RFC3339 timestamp within a year after
+// 2020-01-01T00:00:00Z, the form jsonpb expects for google.protobuf.Timestamp
+// fields. The base is fixed, so the value depends only on r.
+func randomTimestamp(r *Rand) string {
+	base := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	offset := time.Duration(r.pickupEnum(365*24)) * time.Hour
+	return base.Add(offset).Format(time.RFC3339)
+}
+
+// randomDuration returns a whole-second duration string such as "42s", the
+// form jsonpb expects for google.protobuf.Duration fields.
+func randomDuration(r *Rand) string {
+	return fmt.Sprintf("%ds", r.pickupEnum(3600))
+}
+
+// randomFieldMask returns a comma-joined list of dotted field paths, the
+// form jsonpb expects for google.protobuf.FieldMask fields.
+func randomFieldMask(r *Rand) string {
+	words := []string{"id", "name", "value", "data", "meta", "status"}
+	n := 1 + r.pickupEnum(3)
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		segmentCount := 1 + r.pickupEnum(2)
+		segments := make([]string, 0, segmentCount)
+		for j := 0; j < segmentCount; j++ {
+			segments = append(segments, words[r.pickupEnum(len(words))])
 		}
+		paths = append(paths, strings.Join(segments, "."))
+	}
+	return strings.Join(paths, ",")
+}
+
+// randomValue returns an arbitrary JSON-compatible value, the shape
+// google.protobuf.Value accepts.
+func randomValue(r *Rand) interface{} {
+	switch r.pickupEnum(4) {
+	case 0:
+		return r.string()
+	case 1:
+		return r.double()
+	case 2:
+		return r.bool()
+	default:
+		return randomStruct(r)
+	}
+}
+
+// randomStruct returns an arbitrary JSON object, the shape
+// google.protobuf.Struct accepts.
+func randomStruct(r *Rand) map[string]interface{} {
+	n := 1 + r.pickupEnum(3)
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		m[fmt.Sprintf("field%d", i)] = randomValue(r)
 	}
 	return m
 }
+
+// randomListValue returns an arbitrary JSON array, the shape
+// google.protobuf.ListValue accepts.
+func randomListValue(r *Rand) []interface{} {
+	n := 1 + r.pickupEnum(3)
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		arr = append(arr, randomValue(r))
+	}
+	return arr
+}
+
+// randomAny packs a random instance of c.anyType into the
+// {"@type": ..., ...fields} shape jsonpb expects for google.protobuf.Any.
+// Without --any-type there's no way to know which message to pack, so it
+// falls back to an Any packing google.protobuf.Empty.
+func (c *command) randomAny(r *Rand, client *reflect.Client, depth int) map[string]interface{} {
+	typeURL := "type.googleapis.com/" + c.anyType
+	if c.anyType == "" {
+		return map[string]interface{}{"@type": "type.googleapis.com/google.protobuf.Empty"}
+	}
+	if depth >= maxMessageDepth {
+		return map[string]interface{}{"@type": typeURL}
+	}
+	mdesc, err := client.ResolveMessage(c.anyType)
+	if err != nil {
+		return map[string]interface{}{"@type": typeURL}
+	}
+	fields := c.retriveFields(r, client, mdesc.GetFields(), depth+1)
+	fields["@type"] = typeURL
+	return fields
+}
@@ -0,0 +1,34 @@
+package call
+
+import "testing"
+
+func TestDetectServiceMethod(t *testing.T) {
+	cases := []struct {
+		name        string
+		in          string
+		wantService string
+		wantMethod  string
+		wantErr     bool
+	}{
+		{"simple", "test.Test.Echo", "test.Test", "Echo", false},
+		{"no_package", "Test.Echo", "Test", "Echo", false},
+		{"no_dot", "Echo", "", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service, method, err := DetectServiceMethod(c.in)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("DetectServiceMethod(%q) = nil error, want error", c.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectServiceMethod(%q) = %v, want nil error", c.in, err)
+			}
+			if service != c.wantService || method != c.wantMethod {
+				t.Fatalf("DetectServiceMethod(%q) = (%q, %q), want (%q, %q)", c.in, service, method, c.wantService, c.wantMethod)
+			}
+		})
+	}
+}
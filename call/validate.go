@@ -0,0 +1,377 @@
+package call
+
+import (
+	"math/rand"
+	"strings"
+
+	bufvalidate "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/envoyproxy/protoc-gen-validate/validate"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
+	"github.com/jhump/protoreflect/desc"
+	regen "github.com/zach-klippenstein/goregen"
+)
+
+// numericRule is a normalized view of the PGV/buf.validate numeric
+// constraints (gte/gt, lte/lt, in) for any of the int/uint/float/double
+// field kinds.
+type numericRule struct {
+	hasMin   bool
+	min      float64
+	minExcl  bool
+	hasMax   bool
+	max      float64
+	maxExcl  bool
+	in       []float64
+	unsigned bool
+	integer  bool
+}
+
+// floatExclEpsilon nudges a float/double bound past an exclusive gt/lt so
+// generation honors it without relying on integer ±1 step semantics, which
+// would collapse short spans like (0, 1) down to an empty range.
+const floatExclEpsilon = 1e-9
+
+// stringRule is a normalized view of the PGV/buf.validate string
+// constraints.
+type stringRule struct {
+	hasLen   bool
+	len      uint64
+	minLen   uint64
+	maxLen   uint64
+	pattern  string
+	in       []string
+	uuid     bool
+	email    bool
+	hostname bool
+}
+
+// repeatedRule is a normalized view of the PGV/buf.validate collection-size
+// constraints on a repeated field.
+type repeatedRule struct {
+	hasMin   bool
+	minItems uint64
+	hasMax   bool
+	maxItems uint64
+}
+
+// fieldValidateRules reads whichever of protoc-gen-validate's `validate.rules`
+// (field 1071) or buf.validate's `buf.validate.field` (field 1159) extension
+// is present on field, and returns the one that applies. Most fields have
+// neither, in which case both return values are nil.
+func fieldValidateRules(field *desc.FieldDescriptor) (*validate.FieldRules, *bufvalidate.FieldConstraints) {
+	opts := field.GetFieldOptions()
+	if opts == nil {
+		return nil, nil
+	}
+	if proto.HasExtension(opts, validate.E_Rules) {
+		if r, ok := proto.GetExtension(opts, validate.E_Rules).(*validate.FieldRules); ok && r != nil {
+			return r, nil
+		}
+	}
+	if proto.HasExtension(opts, bufvalidate.E_Field) {
+		if r, ok := proto.GetExtension(opts, bufvalidate.E_Field).(*bufvalidate.FieldConstraints); ok && r != nil {
+			return nil, r
+		}
+	}
+	return nil, nil
+}
+
+// numericRuleFor normalizes whichever rule set applies to field into a
+// numericRule, returning ok=false when field carries no numeric constraints.
+func numericRuleFor(field *desc.FieldDescriptor) (numericRule, bool) {
+	pgv, buf := fieldValidateRules(field)
+	if pgv != nil {
+		switch t := pgv.GetType().(type) {
+		case *validate.FieldRules_Int32:
+			ru := t.Int32
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, int32sToFloats(ru.GetIn()))
+			r.integer = true
+			return r, true
+		case *validate.FieldRules_Int64:
+			ru := t.Int64
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, int64sToFloats(ru.GetIn()))
+			r.integer = true
+			return r, true
+		case *validate.FieldRules_Uint32:
+			ru := t.Uint32
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, uint32sToFloats(ru.GetIn()))
+			r.unsigned, r.integer = true, true
+			return r, true
+		case *validate.FieldRules_Uint64:
+			ru := t.Uint64
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, uint64sToFloats(ru.GetIn()))
+			r.unsigned, r.integer = true, true
+			return r, true
+		case *validate.FieldRules_Float:
+			ru := t.Float
+			return newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, float32sToFloats(ru.GetIn())), true
+		case *validate.FieldRules_Double:
+			ru := t.Double
+			return newNumericRule(ru.GetGte(), ru.Gte != nil, ru.GetGt(), ru.Gt != nil,
+				ru.GetLte(), ru.Lte != nil, ru.GetLt(), ru.Lt != nil, ru.GetIn()), true
+		}
+		return numericRule{}, false
+	}
+	if buf != nil {
+		// buf.validate mirrors PGV's per-kind numeric rule shape closely
+		// enough that the same normalization applies.
+		switch t := buf.GetType().(type) {
+		case *bufvalidate.FieldConstraints_Int32:
+			ru := t.Int32
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, int32sToFloats(ru.GetIn()))
+			r.integer = true
+			return r, true
+		case *bufvalidate.FieldConstraints_Int64:
+			ru := t.Int64
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, int64sToFloats(ru.GetIn()))
+			r.integer = true
+			return r, true
+		case *bufvalidate.FieldConstraints_Uint32:
+			ru := t.Uint32
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, uint32sToFloats(ru.GetIn()))
+			r.unsigned, r.integer = true, true
+			return r, true
+		case *bufvalidate.FieldConstraints_Uint64:
+			ru := t.Uint64
+			r := newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, uint64sToFloats(ru.GetIn()))
+			r.unsigned, r.integer = true, true
+			return r, true
+		case *bufvalidate.FieldConstraints_Float:
+			ru := t.Float
+			return newNumericRule(float64(ru.GetGte()), ru.Gte != nil, float64(ru.GetGt()), ru.Gt != nil,
+				float64(ru.GetLte()), ru.Lte != nil, float64(ru.GetLt()), ru.Lt != nil, float32sToFloats(ru.GetIn())), true
+		case *bufvalidate.FieldConstraints_Double:
+			ru := t.Double
+			return newNumericRule(ru.GetGte(), ru.Gte != nil, ru.GetGt(), ru.Gt != nil,
+				ru.GetLte(), ru.Lte != nil, ru.GetLt(), ru.Lt != nil, ru.GetIn()), true
+		}
+	}
+	return numericRule{}, false
+}
+
+func newNumericRule(gte float64, hasGte bool, gt float64, hasGt bool, lte float64, hasLte bool, lt float64, hasLt bool, in []float64) numericRule {
+	r := numericRule{in: in}
+	switch {
+	case hasGte:
+		r.hasMin, r.min = true, gte
+	case hasGt:
+		r.hasMin, r.min, r.minExcl = true, gt, true
+	}
+	switch {
+	case hasLte:
+		r.hasMax, r.max = true, lte
+	case hasLt:
+		r.hasMax, r.max, r.maxExcl = true, lt, true
+	}
+	return r
+}
+
+func int32sToFloats(in []int32) []float64 {
+	out := make([]float64, len(in))
+	for i, v := range in {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func int64sToFloats(in []int64) []float64 {
+	out := make([]float64, len(in))
+	for i, v := range in {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func uint32sToFloats(in []uint32) []float64 {
+	out := make([]float64, len(in))
+	for i, v := range in {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func uint64sToFloats(in []uint64) []float64 {
+	out := make([]float64, len(in))
+	for i, v := range in {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+func float32sToFloats(in []float32) []float64 {
+	out := make([]float64, len(in))
+	for i, v := range in {
+		out[i] = float64(v)
+	}
+	return out
+}
+
+// clampedFloat returns a random float honoring r's bounds, falling back to
+// fallback when r carries no usable constraint.
+func (r numericRule) clampedFloat(rnd *Rand, fallback float64) float64 {
+	if len(r.in) > 0 {
+		return r.in[rnd.pickupEnum(len(r.in))]
+	}
+	min, max := r.min, r.max
+	if r.integer {
+		if r.minExcl {
+			min++
+		}
+		if r.maxExcl {
+			max--
+		}
+	} else {
+		if r.minExcl {
+			min += floatExclEpsilon
+		}
+		if r.maxExcl {
+			max -= floatExclEpsilon
+		}
+	}
+	switch {
+	case r.hasMin && r.hasMax:
+		if max < min {
+			return min
+		}
+		if !r.integer {
+			return min + float64(rnd.pickupEnum(1000000))/1000000*(max-min)
+		}
+		return min + float64(rnd.pickupEnum(int(max-min)+1))
+	case r.hasMin:
+		return min + float64(rnd.pickupEnum(1000))
+	case r.hasMax:
+		floor := 0.0
+		if !r.unsigned {
+			floor = max - 1000
+		}
+		v := max - float64(rnd.pickupEnum(1000))
+		if v < floor {
+			v = floor
+		}
+		return v
+	default:
+		return fallback
+	}
+}
+
+func stringRuleFor(field *desc.FieldDescriptor) (stringRule, bool) {
+	pgv, buf := fieldValidateRules(field)
+	if pgv != nil && pgv.GetString_() != nil {
+		s := pgv.GetString_()
+		return stringRule{
+			hasLen:   s.Len != nil,
+			len:      s.GetLen(),
+			minLen:   s.GetMinLen(),
+			maxLen:   s.GetMaxLen(),
+			pattern:  s.GetPattern(),
+			in:       s.GetIn(),
+			uuid:     s.GetUuid(),
+			email:    s.GetEmail(),
+			hostname: s.GetHostname(),
+		}, true
+	}
+	if buf != nil && buf.GetString_() != nil {
+		s := buf.GetString_()
+		return stringRule{
+			hasLen:   s.Len != nil,
+			len:      s.GetLen(),
+			minLen:   s.GetMinLen(),
+			maxLen:   s.GetMaxLen(),
+			pattern:  s.GetPattern(),
+			in:       s.GetIn(),
+			uuid:     s.GetUuid(),
+			email:    s.GetEmail(),
+			hostname: s.GetHostname(),
+		}, true
+	}
+	return stringRule{}, false
+}
+
+// randomString returns a string honoring r's constraints, preferring the
+// most specific rule available.
+func (r stringRule) randomString(rnd *Rand) string {
+	switch {
+	case len(r.in) > 0:
+		return r.in[rnd.pickupEnum(len(r.in))]
+	case r.uuid:
+		var b uuid.UUID
+		for i := range b {
+			b[i] = byte(rnd.pickupEnum(256))
+		}
+		b[6] = b[6]&0x0f | 0x40 // version 4
+		b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+		return b.String()
+	case r.email:
+		return rnd.string() + "@example.com"
+	case r.hostname:
+		return rnd.string() + ".example.com"
+	case r.pattern != "":
+		args := &regen.GeneratorArgs{RngSource: rand.NewSource(int64(rnd.uint64()))}
+		if g, err := regen.NewGenerator(r.pattern, args); err == nil {
+			return g.Generate()
+		}
+	}
+	n := int(r.len)
+	if !r.hasLen {
+		n = int(r.minLen)
+		if max := int(r.maxLen); max > n {
+			n += rnd.pickupEnum(max - n + 1)
+		} else if n == 0 {
+			n = 8
+		}
+	}
+	var sb strings.Builder
+	for sb.Len() < n {
+		sb.WriteString(rnd.string())
+	}
+	return sb.String()[:n]
+}
+
+func repeatedRuleFor(field *desc.FieldDescriptor) (repeatedRule, bool) {
+	pgv, buf := fieldValidateRules(field)
+	if pgv != nil && pgv.GetRepeated() != nil {
+		rr := pgv.GetRepeated()
+		return repeatedRule{
+			hasMin: rr.MinItems != nil, minItems: rr.GetMinItems(),
+			hasMax: rr.MaxItems != nil, maxItems: rr.GetMaxItems(),
+		}, true
+	}
+	if buf != nil && buf.GetRepeated() != nil {
+		rr := buf.GetRepeated()
+		return repeatedRule{
+			hasMin: rr.MinItems != nil, minItems: rr.GetMinItems(),
+			hasMax: rr.MaxItems != nil, maxItems: rr.GetMaxItems(),
+		}, true
+	}
+	return repeatedRule{}, false
+}
+
+// itemCount picks how many elements to generate for a repeated field,
+// honoring min_items/max_items when declared and falling back to the
+// unconstrained 1-5 range otherwise.
+func (r repeatedRule) itemCount(rnd *Rand) int {
+	min := 1
+	if r.hasMin {
+		min = int(r.minItems)
+	} else if r.hasMax {
+		min = 0
+	}
+	max := min + 4
+	if r.hasMax {
+		max = int(r.maxItems)
+	}
+	if max < min {
+		max = min
+	}
+	return min + rnd.pickupEnum(max-min+1)
+}
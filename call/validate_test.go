@@ -0,0 +1,63 @@
+package call
+
+import "testing"
+
+func TestNumericRuleClampedFloatFloatExclusiveBounds(t *testing.T) {
+	// A common buf.validate shape for a ratio/probability field:
+	// double.gt = 0, double.lt = 1. Integer ±1 exclusivity math would
+	// collapse this span to nothing and return an out-of-range value.
+	r := numericRule{hasMin: true, min: 0, minExcl: true, hasMax: true, max: 1, maxExcl: true}
+	rnd := NewSeededRand(1)
+	for i := 0; i < 100; i++ {
+		v := r.clampedFloat(rnd, 0.5)
+		if v <= 0 || v >= 1 {
+			t.Fatalf("clampedFloat(gt=0, lt=1) = %v, want in (0, 1)", v)
+		}
+	}
+}
+
+func TestNumericRuleClampedFloatIntegerExclusiveBounds(t *testing.T) {
+	r := numericRule{hasMin: true, min: 0, minExcl: true, hasMax: true, max: 3, maxExcl: true, integer: true}
+	rnd := NewSeededRand(1)
+	for i := 0; i < 100; i++ {
+		v := r.clampedFloat(rnd, 1)
+		if v < 1 || v > 2 {
+			t.Fatalf("clampedFloat(gt=0, lt=3, integer) = %v, want in [1, 2]", v)
+		}
+	}
+}
+
+func TestNumericRuleClampedFloatMaxOnlyUnsignedFloor(t *testing.T) {
+	r := numericRule{hasMax: true, max: 10, unsigned: true, integer: true}
+	rnd := NewSeededRand(1)
+	for i := 0; i < 100; i++ {
+		if v := r.clampedFloat(rnd, 0); v < 0 {
+			t.Fatalf("clampedFloat(lt=10, unsigned) = %v, want >= 0", v)
+		}
+	}
+}
+
+func TestRepeatedRuleItemCount(t *testing.T) {
+	rnd := NewSeededRand(1)
+	cases := []struct {
+		name string
+		r    repeatedRule
+		min  int
+		max  int
+	}{
+		{"unconstrained", repeatedRule{}, 1, 5},
+		{"min_only", repeatedRule{hasMin: true, minItems: 3}, 3, 7},
+		{"max_only", repeatedRule{hasMax: true, maxItems: 0}, 0, 0},
+		{"min_and_max", repeatedRule{hasMin: true, minItems: 2, hasMax: true, maxItems: 4}, 2, 4},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				n := c.r.itemCount(rnd)
+				if n < c.min || n > c.max {
+					t.Fatalf("itemCount() = %d, want in [%d, %d]", n, c.min, c.max)
+				}
+			}
+		})
+	}
+}
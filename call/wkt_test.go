@@ -0,0 +1,42 @@
+package call
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRandomTimestampIsRFC3339(t *testing.T) {
+	r := NewSeededRand(1)
+	for i := 0; i < 20; i++ {
+		s := randomTimestamp(r)
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			t.Fatalf("randomTimestamp() = %q, want RFC3339: %v", s, err)
+		}
+	}
+}
+
+func TestRandomDurationHasSecondsSuffix(t *testing.T) {
+	r := NewSeededRand(1)
+	for i := 0; i < 20; i++ {
+		s := randomDuration(r)
+		if !strings.HasSuffix(s, "s") {
+			t.Fatalf("randomDuration() = %q, want a trailing %q", s, "s")
+		}
+	}
+}
+
+func TestRandomFieldMaskIsCommaJoinedDottedPaths(t *testing.T) {
+	r := NewSeededRand(1)
+	for i := 0; i < 20; i++ {
+		s := randomFieldMask(r)
+		if s == "" {
+			t.Fatalf("randomFieldMask() returned empty string")
+		}
+		for _, path := range strings.Split(s, ",") {
+			if path == "" {
+				t.Fatalf("randomFieldMask() = %q, contains an empty path", s)
+			}
+		}
+	}
+}